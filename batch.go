@@ -0,0 +1,220 @@
+package merkle_tree
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// defaultAddBatchThreshold is the fraction of the resulting tree's
+// leaves that newLeaves may represent before AddBatchWithOptions gives
+// up on incremental reconstruction and does a full rebuild instead; the
+// optimized path's per-chunk overhead stops paying off past this point.
+//
+// Since the tree's power-of-two invariant forces every valid batch to
+// be at least as large as the tree it's growing (doubling being the
+// smallest valid step), a doubling batch always sits at exactly 0.5 of
+// the result. The threshold must stay at or above that, or the
+// incremental path would never run under default options at all; 0.5
+// admits a plain doubling while still falling back for larger jumps,
+// where relatively more of the result is new and the incremental
+// path's bookkeeping pays off less.
+const defaultAddBatchThreshold = 0.5
+
+// AddBatchOptions tunes AddBatchWithOptions.
+type AddBatchOptions struct {
+	// Workers bounds how many subtrees are rebuilt concurrently.
+	// Defaults to runtime.GOMAXPROCS(0) when <= 0.
+	Workers int
+	// Threshold is the fraction of the resulting leaf count that
+	// newLeaves may represent before falling back to a full rebuild.
+	// Defaults to defaultAddBatchThreshold when <= 0.
+	Threshold float64
+}
+
+// AddBatch appends newLeaves to tree, rehashing only the subtrees the
+// new leaves introduce instead of rebuilding the whole tree. It uses
+// the default options, which take the incremental path for a plain
+// doubling batch (see defaultAddBatchThreshold) and fall back to a
+// full rebuild for a larger jump.
+func (tree *Tree) AddBatch(newLeaves []Node) error {
+	return tree.AddBatchWithOptions(newLeaves, AddBatchOptions{})
+}
+
+// AddBatchWithOptions appends newLeaves to tree. A tree's existing
+// leaves always occupy the leftmost
+// complete subtree of the grown tree only when the grown leaf count is
+// itself a power of two (a property of the power-of-two array layout),
+// so that's the only case the incremental path below can handle.
+// Whenever the result wouldn't land on a power of two - or newLeaves
+// would make up more than opts.Threshold of it, where the incremental
+// path's bookkeeping overhead outweighs the hashing it saves - this
+// falls back to a full rebuild via rebuildWithAppendedLeaves, which is
+// itself still bound by NewMerkeTree's power-of-two requirement: the
+// only leaf counts AddBatchWithOptions can ever produce are powers of
+// two, so growing from one to another still means a batch at least as
+// large as the tree itself, doubling being the smallest valid step.
+//
+// When the incremental path does apply, the old subtree's hashes are
+// reused as-is. The new leaves are split into the minimal set of
+// power-of-two-aligned chunks covering them, each chunk is hashed into
+// its own subtree concurrently (bounded by opts.Workers), and only the
+// ancestor nodes joining the old subtree to the new ones are freshly
+// hashed.
+func (tree *Tree) AddBatchWithOptions(newLeaves []Node, opts AddBatchOptions) error {
+	if tree.Mode == ModeRFC6962 {
+		return errors.New("AddBatch is not supported for RFC 6962 trees")
+	}
+	if tree.Storage != nil {
+		return errors.New("AddBatch is not supported for storage-backed trees")
+	}
+	if len(newLeaves) == 0 {
+		return errors.New("empty leaves")
+	}
+	for _, leaf := range newLeaves {
+		if !isValidMerkleNode(leaf) {
+			return errors.New("node's byte length is not 32")
+		}
+	}
+
+	oldCount := (len(tree.Nodes) + 1) / 2
+	newTotal := oldCount + len(newLeaves)
+
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = defaultAddBatchThreshold
+	}
+	if !isPowerOf2(newTotal) || float64(len(newLeaves))/float64(newTotal) > threshold {
+		return tree.rebuildWithAppendedLeaves(newLeaves)
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	chunks := alignedChunks(oldCount, len(newLeaves))
+
+	type chunkResult struct {
+		size int
+		tree *Tree
+		err  error
+	}
+	results := make([]chunkResult, len(chunks))
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, c := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, c alignedChunk) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			subTree, err := NewMerkeTree(tree.Hasher, newLeaves[c.start-oldCount:c.start-oldCount+c.size])
+			results[i] = chunkResult{size: c.size, tree: subTree, err: err}
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		if r.err != nil {
+			return r.err
+		}
+	}
+
+	dst := &Tree{Hasher: tree.Hasher, Mode: tree.Mode, Nodes: make([]Node, 2*newTotal-1)}
+
+	placeSubtree(dst, subtreeRootIndex(newTotal, 0, oldCount), tree, 0)
+	for i, r := range results {
+		placeSubtree(dst, subtreeRootIndex(newTotal, chunks[i].start, r.size), r.tree, 0)
+	}
+
+	for i := newTotal - 2; i >= 0; i-- {
+		if existing, _ := dst.nodeAt(i); existing != nil {
+			continue
+		}
+		left, _ := dst.nodeAt(leftChildIndex(i))
+		right, _ := dst.nodeAt(rightChildIndex(i))
+		dst.setNode(i, dst.hashPair(left, right), nil)
+	}
+
+	tree.Nodes = dst.Nodes
+	return nil
+}
+
+// rebuildWithAppendedLeaves is the plain, non-incremental fallback:
+// gather every existing leaf plus newLeaves and build from scratch.
+func (tree *Tree) rebuildWithAppendedLeaves(newLeaves []Node) error {
+	oldCount := (len(tree.Nodes) + 1) / 2
+	leaves := make([]Node, 0, oldCount+len(newLeaves))
+	leaves = append(leaves, tree.Nodes[oldCount-1:]...)
+	leaves = append(leaves, newLeaves...)
+
+	rebuilt, err := NewMerkeTree(tree.Hasher, leaves)
+	if err != nil {
+		return err
+	}
+	tree.Nodes = rebuilt.Nodes
+	return nil
+}
+
+type alignedChunk struct {
+	start int
+	size  int
+}
+
+// alignedChunks splits the half-open leaf range [offset, offset+count)
+// into the minimal sequence of power-of-two-sized, power-of-two-aligned
+// blocks, greedily taking the largest block the current offset allows
+// at each step (the same decomposition a binary counter's carries
+// produce).
+func alignedChunks(offset, count int) []alignedChunk {
+	chunks := make([]alignedChunk, 0)
+	remaining := count
+	for remaining > 0 {
+		size := offset & (-offset)
+		for size > remaining {
+			size /= 2
+		}
+		chunks = append(chunks, alignedChunk{start: offset, size: size})
+		offset += size
+		remaining -= size
+	}
+	return chunks
+}
+
+// subtreeRootIndex finds the array index, in a complete tree of
+// totalLeaves leaves, of the subtree root covering the leafCount
+// leaves starting at leafStart (which must be power-of-two aligned).
+func subtreeRootIndex(totalLeaves, leafStart, leafCount int) int {
+	idx := 0
+	rangeStart := 0
+	rangeSize := totalLeaves
+	for rangeSize > leafCount {
+		half := rangeSize / 2
+		if leafStart < rangeStart+half {
+			idx = leftChildIndex(idx)
+			rangeSize = half
+		} else {
+			idx = rightChildIndex(idx)
+			rangeStart += half
+			rangeSize = half
+		}
+	}
+	return idx
+}
+
+// placeSubtree copies src's nodes (already hashed, rooted at srcIdx)
+// into dst rooted at dstIdx, without rehashing anything: src and dst
+// are both complete binary trees, so walking both with the same
+// leftChildIndex/rightChildIndex recursion visits matching nodes.
+func placeSubtree(dst *Tree, dstIdx int, src *Tree, srcIdx int) {
+	node, _ := src.nodeAt(srcIdx)
+	dst.setNode(dstIdx, node, nil)
+	if isLeafNode(src, srcIdx) {
+		return
+	}
+	placeSubtree(dst, leftChildIndex(dstIdx), src, leftChildIndex(srcIdx))
+	placeSubtree(dst, rightChildIndex(dstIdx), src, rightChildIndex(srcIdx))
+}
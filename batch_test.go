@@ -0,0 +1,167 @@
+package merkle_tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestAddBatch_MatchesFullRebuild(t *testing.T) {
+	cases := []struct {
+		oldCount int
+		addCount int
+	}{
+		{1, 1},
+		{4, 4},
+		{4, 12},
+		{8, 8},
+		{16, 16},
+		{32, 32},
+	}
+
+	for _, c := range cases {
+		all := generateLeaves(c.oldCount + c.addCount)
+		oldLeaves := all[:c.oldCount]
+		newLeaves := all[c.oldCount:]
+
+		tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), oldLeaves)
+		require.NoError(t, err)
+
+		require.NoError(t, tree.AddBatchWithOptions(newLeaves, AddBatchOptions{Threshold: 1.0}))
+		require.True(t, tree.Verify())
+
+		want, err := NewMerkeTree(sha3.NewLegacyKeccak256(), all)
+		require.NoError(t, err)
+		require.Equal(t, want.Nodes, tree.Nodes)
+
+		leavesLength := len(all)
+		for i := 0; i < leavesLength; i++ {
+			proof, err := tree.GetProofByIndex(leavesLength - 1 + i)
+			require.NoError(t, err)
+			root, err := tree.ProcessProof(all[i], proof)
+			require.NoError(t, err)
+			require.Equal(t, tree.Root(), root)
+		}
+	}
+}
+
+func TestAddBatch_FallsBackToFullRebuildAboveThreshold(t *testing.T) {
+	oldLeaves := generateLeaves(4)
+	all := generateLeaves(8)
+	newLeaves := all[4:]
+
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), oldLeaves)
+	require.NoError(t, err)
+
+	require.NoError(t, tree.AddBatchWithOptions(newLeaves, AddBatchOptions{Threshold: 0.1}))
+
+	want, err := NewMerkeTree(sha3.NewLegacyKeccak256(), all)
+	require.NoError(t, err)
+	require.Equal(t, want.Nodes, tree.Nodes)
+}
+
+// A batch that doesn't land the tree on a power of two falls back to
+// rebuildWithAppendedLeaves rather than erroring out of AddBatch
+// itself - but since NewMerkeTree requires a power-of-two leaf count
+// of every tree, the rebuild still fails, just from that shared source
+// instead of a redundant check here.
+func TestAddBatch_RejectsNonPowerOfTwoTotal(t *testing.T) {
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), generateLeaves(4))
+	require.NoError(t, err)
+
+	err = tree.AddBatch(generateLeaves(3))
+	require.Error(t, err)
+}
+
+func TestAddBatch_RejectsEmptyLeaves(t *testing.T) {
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), generateLeaves(4))
+	require.NoError(t, err)
+
+	err = tree.AddBatch(nil)
+	require.Error(t, err)
+}
+
+func TestAddBatch_RejectsRFC6962Trees(t *testing.T) {
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), generateLeaves(4), ModeRFC6962)
+	require.NoError(t, err)
+
+	err = tree.AddBatch(generateLeaves(4))
+	require.Error(t, err)
+}
+
+func TestAddBatch_RejectsStorageBackedTrees(t *testing.T) {
+	tree, err := NewMerkleTreeWithStorage(sha3.NewLegacyKeccak256(), NewMemStorage(), generateLeaves(4))
+	require.NoError(t, err)
+
+	err = tree.AddBatch(generateLeaves(4))
+	require.Error(t, err)
+}
+
+// Benchmarking fixed batch sizes of 1, 10, 100 and 1000 against a single
+// 2^16-leaf tree, as originally asked for, is not possible: two distinct
+// powers of two never sum to a third, and ModeSortedPair trees are
+// always power-of-two leaves (NewMerkeTree's own requirement), so
+// growing one to another valid size needs a batch at least as large as
+// the tree itself - doubling is the smallest valid growth. These
+// benchmarks compare doubling a tree against a full rebuild at a few
+// increasing scales instead.
+//
+// benchmarkAddBatchIncrementalDouble calls plain tree.AddBatch, with no
+// options, so it only measures the incremental path as long as
+// defaultAddBatchThreshold stays at or above 0.5 - a doubling batch is
+// always exactly half the resulting tree. If that ever changes, this
+// benchmark would silently start measuring rebuildWithAppendedLeaves
+// instead, the way it used to.
+func benchmarkAddBatchIncrementalDouble(b *testing.B, oldCount int) {
+	all := generateLeaves(2 * oldCount)
+	baseLeaves := all[:oldCount]
+	newLeaves := all[oldCount:]
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), baseLeaves)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+
+		if err := tree.AddBatch(newLeaves); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkAddBatchFullRebuildDouble(b *testing.B, oldCount int) {
+	all := generateLeaves(2 * oldCount)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewMerkeTree(sha3.NewLegacyKeccak256(), all); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAddBatch_Incremental_Double64(b *testing.B) { benchmarkAddBatchIncrementalDouble(b, 64) }
+func BenchmarkAddBatch_Incremental_Double1024(b *testing.B) {
+	benchmarkAddBatchIncrementalDouble(b, 1024)
+}
+func BenchmarkAddBatch_Incremental_Double16384(b *testing.B) {
+	benchmarkAddBatchIncrementalDouble(b, 16384)
+}
+func BenchmarkAddBatch_Incremental_Double65536(b *testing.B) {
+	benchmarkAddBatchIncrementalDouble(b, 65536)
+}
+
+func BenchmarkAddBatch_FullRebuild_Double64(b *testing.B) { benchmarkAddBatchFullRebuildDouble(b, 64) }
+func BenchmarkAddBatch_FullRebuild_Double1024(b *testing.B) {
+	benchmarkAddBatchFullRebuildDouble(b, 1024)
+}
+func BenchmarkAddBatch_FullRebuild_Double16384(b *testing.B) {
+	benchmarkAddBatchFullRebuildDouble(b, 16384)
+}
+func BenchmarkAddBatch_FullRebuild_Double65536(b *testing.B) {
+	benchmarkAddBatchFullRebuildDouble(b, 65536)
+}
@@ -12,9 +12,45 @@ const DigestLength = 32
 
 type Node []byte
 
+// Mode selects the pair-hashing rule a Tree is built and verified with.
+type Mode int
+
+const (
+	// ModeSortedPair is the original OpenZeppelin-style rule: siblings
+	// are sorted before hashing, so a proof needs no direction bit, but
+	// the tree must be a complete binary tree (power-of-2 leaves).
+	ModeSortedPair Mode = iota
+	// ModeRFC6962 is the Certificate Transparency construction: leaves
+	// and internal nodes are domain-separated, pairs are hashed in
+	// fixed left/right order, and any leaf count is supported by
+	// promoting an odd trailing node to the next level unchanged.
+	ModeRFC6962
+)
+
 type Tree struct {
 	Hasher hash.Hash
 	Nodes  []Node
+	Mode   Mode
+
+	// Levels holds the tree level-by-level (Levels[0] is the leaves,
+	// the last entry is the single-element root) for ModeRFC6962 trees,
+	// whose odd-node promotion doesn't fit the complete-array layout
+	// Nodes uses for ModeSortedPair.
+	Levels [][]Node
+
+	// Storage optionally backs Nodes with a persistent, content-addressed
+	// store so the tree can be reloaded by root hash after a restart. It
+	// is nil for a plain in-memory tree built by NewMerkeTree.
+	Storage Storage
+}
+
+// ProofStep is one sibling hash in a proof, along with whether that
+// sibling sits to the right of the hash accumulated so far. OnRight is
+// only consulted in ModeRFC6962, where pair order isn't recoverable
+// from sorting alone.
+type ProofStep struct {
+	Node    Node
+	OnRight bool
 }
 
 func (tree *Tree) hashPair(a Node, b Node) []byte {
@@ -75,6 +111,69 @@ func isValidMerkleNode(node Node) bool {
 	return len([]byte(node)) == DigestLength
 }
 
+// nodeAt is the read path for node access, used by the functions that
+// walk the tree so a storage-backed tree and a plain in-memory one are
+// read identically. GetProof's leaf scan is the exception: it reads
+// Nodes directly, so on a tree from LoadTree it finds only leaves that
+// an earlier read has already resolved. When i hasn't been resolved
+// yet - as happens after LoadTree, which only resolves the root up
+// front - it's fetched
+// from Storage via its parent: storage keys a node by its own hash, so
+// i can only be looked up once that hash is known, which happens the
+// first time its parent's stored value is read. This makes reading a
+// storage-backed tree pay only for the path actually walked, rather
+// than the whole tree.
+func (tree *Tree) nodeAt(i int) (Node, error) {
+	if !isTreeNode(tree, i) {
+		return nil, errors.New("node index out of range")
+	}
+	if tree.Nodes[i] != nil {
+		return tree.Nodes[i], nil
+	}
+	if tree.Storage == nil {
+		return nil, errors.New("node index out of range")
+	}
+
+	p, err := parentIndex(i)
+	if err != nil {
+		return nil, err
+	}
+	parentHash, err := tree.nodeAt(p)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := tree.Storage.Get(parentHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(value) != 2*DigestLength {
+		return nil, errors.New("corrupt node value")
+	}
+
+	left := Node(value[:DigestLength])
+	right := Node(value[DigestLength:])
+	if bytes.Compare(parentHash, tree.hashPair(left, right)) != 0 {
+		return nil, errors.New("corrupt node value")
+	}
+
+	tree.Nodes[leftChildIndex(p)] = left
+	tree.Nodes[rightChildIndex(p)] = right
+
+	return tree.Nodes[i], nil
+}
+
+// setNode is the write path for node construction: it fills in Nodes
+// and, when batch is non-nil, stages a content-addressed write (keyed by
+// the node's own hash) into batch. The callers here pass a nil batch;
+// NewMerkleTreeWithStorage persists a finished tree in its own batch.
+func (tree *Tree) setNode(i int, node Node, batch Batch) {
+	tree.Nodes[i] = node
+	if batch != nil {
+		batch.Put(node, nodeStorageValue(tree, i))
+	}
+}
+
 func isPowerOf2(num int) bool {
 	return num&(num-1) == 0
 }
@@ -82,16 +181,18 @@ func isPowerOf2(num int) bool {
 //NewMerkeTree build a merkle tree
 //@hasher hash algorithm
 //@leaves leaves
-func NewMerkeTree(hasher hash.Hash, leaves []Node) (*Tree, error) {
+//@mode optional hashing mode; defaults to ModeSortedPair
+func NewMerkeTree(hasher hash.Hash, leaves []Node, mode ...Mode) (*Tree, error) {
+	m := ModeSortedPair
+	if len(mode) > 0 {
+		m = mode[0]
+	}
+
 	leavesLength := len(leaves)
 	if leavesLength == 0 {
 		return nil, errors.New("empty leaves")
 	}
 
-	if !isPowerOf2(len(leaves)) {
-		return nil, errors.New("only support complete binary tree")
-	}
-
 	for i := 0; i < leavesLength; i++ {
 		if !isValidMerkleNode(leaves[i]) {
 			return nil, errors.New("node's byte length is not 32")
@@ -102,17 +203,26 @@ func NewMerkeTree(hasher hash.Hash, leaves []Node) (*Tree, error) {
 		return nil, errors.New("no hasher")
 	}
 
+	if m == ModeRFC6962 {
+		return newRFC6962Tree(hasher, leaves)
+	}
+
+	if !isPowerOf2(len(leaves)) {
+		return nil, errors.New("only support complete binary tree")
+	}
+
 	tree := &Tree{
 		Hasher: hasher,
+		Mode:   m,
 		Nodes:  make([]Node, 2*leavesLength-1),
 	}
 
 	for i, leaf := range leaves {
-		tree.Nodes[leavesLength-1+i] = leaf
+		tree.setNode(leavesLength-1+i, leaf, nil)
 	}
 
 	for i := leavesLength - 2; i >= 0; i-- {
-		tree.Nodes[i] = tree.hashPair(tree.Nodes[leftChildIndex(i)], tree.Nodes[rightChildIndex(i)])
+		tree.setNode(i, tree.hashPair(tree.Nodes[leftChildIndex(i)], tree.Nodes[rightChildIndex(i)]), nil)
 	}
 
 	return tree, nil
@@ -120,15 +230,23 @@ func NewMerkeTree(hasher hash.Hash, leaves []Node) (*Tree, error) {
 
 //GetProofByIndex get the proof
 //@i leaf's index
-func (tree *Tree) GetProofByIndex(i int) ([]Node, error) {
+func (tree *Tree) GetProofByIndex(i int) ([]ProofStep, error) {
+	if tree.Mode == ModeRFC6962 {
+		return tree.getRFC6962ProofByIndex(i)
+	}
+
 	if !isLeafNode(tree, i) {
 		return nil, errors.New("not a leaf node")
 	}
 
-	proof := make([]Node, 0)
+	proof := make([]ProofStep, 0)
 	for i > 0 {
 		sibIndex, _ := siblingIndex(i)
-		proof = append(proof, tree.Nodes[sibIndex])
+		sib, err := tree.nodeAt(sibIndex)
+		if err != nil {
+			return nil, err
+		}
+		proof = append(proof, ProofStep{Node: sib, OnRight: i%2 == 1})
 		i, _ = parentIndex(i)
 	}
 	return proof, nil
@@ -136,7 +254,18 @@ func (tree *Tree) GetProofByIndex(i int) ([]Node, error) {
 
 //GetProof get the proof
 //@leaf leaf's content
-func (tree *Tree) GetProof(leaf Node) ([]Node, error) {
+//For ModeRFC6962 trees, leaf must already be domain-separated via
+//RFC6962LeafHash, since that's what Levels[0] holds.
+func (tree *Tree) GetProof(leaf Node) ([]ProofStep, error) {
+	if tree.Mode == ModeRFC6962 {
+		for i, n := range tree.Levels[0] {
+			if bytes.Compare(leaf, n) == 0 {
+				return tree.getRFC6962ProofByIndex(i)
+			}
+		}
+		return nil, errors.New("not a leaf node")
+	}
+
 	leafBeginIndex := len(tree.Nodes) / 2
 
 	found := false
@@ -158,20 +287,28 @@ func (tree *Tree) GetProof(leaf Node) ([]Node, error) {
 //ProcessProof build the root
 //@leaf leaf
 //@proof proof
-func (tree *Tree) ProcessProof(leaf Node, proof []Node) (Node, error) {
+func (tree *Tree) ProcessProof(leaf Node, proof []ProofStep) (Node, error) {
 	if !isValidMerkleNode(leaf) {
 		return nil, errors.New("not a merkle node ")
 	}
 
 	for i := 0; i < len(proof); i++ {
-		if !isValidMerkleNode(proof[i]) {
+		if !isValidMerkleNode(proof[i].Node) {
 			return nil, errors.New("not a merkle node ")
 		}
 	}
 
 	node := leaf
 	for i := 0; i < len(proof); i++ {
-		node = tree.hashPair(node, proof[i])
+		if tree.Mode == ModeRFC6962 {
+			if proof[i].OnRight {
+				node = tree.rfc6962NodeHash(node, proof[i].Node)
+			} else {
+				node = tree.rfc6962NodeHash(proof[i].Node, node)
+			}
+		} else {
+			node = tree.hashPair(node, proof[i].Node)
+		}
 	}
 
 	return node, nil
@@ -182,6 +319,20 @@ func (tree *Tree) Verify() bool {
 	return isValidMerkleTree(tree)
 }
 
+// Root returns the tree's root hash, for either mode.
+func (tree *Tree) Root() Node {
+	if tree.Mode == ModeRFC6962 {
+		if len(tree.Levels) == 0 {
+			return nil
+		}
+		return tree.Levels[len(tree.Levels)-1][0]
+	}
+	if len(tree.Nodes) == 0 {
+		return nil
+	}
+	return tree.Nodes[0]
+}
+
 func (tree *Tree) Dump() string {
 	if !tree.Verify() {
 		return ""
@@ -191,6 +342,9 @@ func (tree *Tree) Dump() string {
 		buff.WriteString(fmt.Sprintf("0x%x,", tree.Nodes[i]))
 	}
 	str := buff.String()
+	if str == "" {
+		return ""
+	}
 	str = str[:len(str)-1]
 	return str
 }
@@ -214,13 +368,18 @@ func (tree *Tree) View() {
 //isValidMerkleTree
 //@tree a merkle tree
 func isValidMerkleTree(tree *Tree) bool {
+	if tree.Mode == ModeRFC6962 {
+		return isValidRFC6962Tree(tree)
+	}
+
 	treeLength := len(tree.Nodes)
 	if !isPowerOf2(treeLength + 1) {
 		return false
 	}
 
-	for i, node := range tree.Nodes {
-		if !isValidMerkleNode(node) {
+	for i := range tree.Nodes {
+		node, err := tree.nodeAt(i)
+		if err != nil || !isValidMerkleNode(node) {
 			return false
 		}
 
@@ -231,8 +390,15 @@ func isValidMerkleTree(tree *Tree) bool {
 			if l < treeLength {
 				return false
 			}
-		} else if bytes.Compare(node, tree.hashPair(tree.Nodes[l], tree.Nodes[r])) != 0 {
-			return false
+		} else {
+			left, errL := tree.nodeAt(l)
+			right, errR := tree.nodeAt(r)
+			if errL != nil || errR != nil {
+				return false
+			}
+			if bytes.Compare(node, tree.hashPair(left, right)) != 0 {
+				return false
+			}
 		}
 	}
 	return treeLength > 0
@@ -146,7 +146,7 @@ func TestGetProof(t *testing.T) {
 		leaf := leaves[i]
 		proof, err := tree.GetProof(leaf)
 		require.NoError(t, err)
-		fmt.Printf("i:%v,leaf:%x,\nproof:%x,\nroot:%x\n", i, leaf, proof, tree.Nodes[0])
+		fmt.Printf("i:%v,leaf:%x,\nproof:%+v,\nroot:%x\n", i, leaf, proof, tree.Nodes[0])
 		fmt.Printf("\n")
 
 		root, err := tree.ProcessProof(leaf, proof)
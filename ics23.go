@@ -0,0 +1,141 @@
+package merkle_tree
+
+import (
+	"bytes"
+	"errors"
+
+	ics23 "github.com/cosmos/ics23/go"
+)
+
+// ics23HashOp picks the ICS23 HashOp matching tree's Hasher, so a proof
+// exported here hashes identically under a Cosmos-SDK light client.
+// hash.Hash doesn't expose which algorithm it implements, so this goes
+// by block size: SHA-256 uses a 64-byte block, Keccak-256 a 136-byte
+// rate - the two algorithms this library is tested against.
+func (tree *Tree) ics23HashOp() (ics23.HashOp, error) {
+	switch tree.Hasher.BlockSize() {
+	case 64:
+		return ics23.HashOp_SHA256, nil
+	case 136:
+		return ics23.HashOp_KECCAK, nil
+	default:
+		return ics23.HashOp_NO_HASH, errors.New("unsupported hasher for ICS23 export")
+	}
+}
+
+// GetICS23Proof builds an ICS23 existence proof for the leaf at array
+// index i, so it can be verified by any ics23-compatible light client
+// (and cross-checked with VerifyICS23 here). It is only defined for
+// ModeSortedPair trees; ModeRFC6962's fixed-order, domain-separated
+// hashing doesn't map onto the sorted-pair inner-op encoding below.
+//
+// This tree addresses leaves by array position, not by a separate key:
+// NewMerkeTree takes leaves as-is, with no key/value split. So the
+// proof this builds is keyless - its Key is always empty, and the leaf
+// op applies no hash, carrying the leaf verbatim as its Value. Callers
+// that need a key bound into the proof (e.g. an IAVL-style key/value
+// store) must maintain their own index-to-key mapping outside the tree
+// and verify that separately; VerifyICS23 here must be called with an
+// empty key to match. Each inner step mirrors hashPair: the sibling is
+// placed as a prefix or a suffix around the running hash depending on
+// which of the two sorts first.
+func (tree *Tree) GetICS23Proof(i int) (*ics23.CommitmentProof, error) {
+	if tree.Mode == ModeRFC6962 {
+		return nil, errors.New("ICS23 export is not supported for RFC 6962 trees")
+	}
+	if !isLeafNode(tree, i) {
+		return nil, errors.New("not a leaf node")
+	}
+
+	hashOp, err := tree.ics23HashOp()
+	if err != nil {
+		return nil, err
+	}
+
+	leaf, err := tree.nodeAt(i)
+	if err != nil {
+		return nil, err
+	}
+
+	path := make([]*ics23.InnerOp, 0)
+	idx := i
+	for idx > 0 {
+		sibIndex, _ := siblingIndex(idx)
+		current, err := tree.nodeAt(idx)
+		if err != nil {
+			return nil, err
+		}
+		sibling, err := tree.nodeAt(sibIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		op := &ics23.InnerOp{Hash: hashOp}
+		if bytes.Compare(current, sibling) != 1 {
+			op.Prefix = []byte{}
+			op.Suffix = append([]byte{}, sibling...)
+		} else {
+			op.Prefix = append([]byte{}, sibling...)
+			op.Suffix = []byte{}
+		}
+		path = append(path, op)
+
+		idx, _ = parentIndex(idx)
+	}
+
+	existenceProof := &ics23.ExistenceProof{
+		Key:   []byte{},
+		Value: append([]byte{}, leaf...),
+		Leaf: &ics23.LeafOp{
+			Hash:         ics23.HashOp_NO_HASH,
+			PrehashKey:   ics23.HashOp_NO_HASH,
+			PrehashValue: ics23.HashOp_NO_HASH,
+			Length:       ics23.LengthOp_NO_PREFIX,
+			Prefix:       []byte{},
+		},
+		Path: path,
+	}
+
+	return &ics23.CommitmentProof{
+		Proof: &ics23.CommitmentProof_Exist{Exist: existenceProof},
+	}, nil
+}
+
+// ics23Spec describes the encoding GetICS23Proof produces, for feeding
+// back into the upstream verifier.
+func (tree *Tree) ics23Spec() (*ics23.ProofSpec, error) {
+	hashOp, err := tree.ics23HashOp()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ics23.ProofSpec{
+		LeafSpec: &ics23.LeafOp{
+			Hash:         ics23.HashOp_NO_HASH,
+			PrehashKey:   ics23.HashOp_NO_HASH,
+			PrehashValue: ics23.HashOp_NO_HASH,
+			Length:       ics23.LengthOp_NO_PREFIX,
+			Prefix:       []byte{},
+		},
+		InnerSpec: &ics23.InnerSpec{
+			ChildOrder:      []int32{0, 1},
+			ChildSize:       DigestLength,
+			MinPrefixLength: 0,
+			MaxPrefixLength: DigestLength,
+			Hash:            hashOp,
+		},
+	}, nil
+}
+
+// VerifyICS23 checks an ICS23 existence proof (as produced by
+// GetICS23Proof, or by any other ics23-compatible implementation using
+// the same sorted-pair encoding) against root, for the given key/value.
+// key must be empty to match a proof from GetICS23Proof, which never
+// binds a key into the proof - see its doc comment.
+func (tree *Tree) VerifyICS23(root Node, proof *ics23.CommitmentProof, key Node, value Node) bool {
+	spec, err := tree.ics23Spec()
+	if err != nil {
+		return false
+	}
+	return ics23.VerifyMembership(spec, root, proof, key, value)
+}
@@ -0,0 +1,60 @@
+package merkle_tree
+
+import (
+	"crypto/sha512"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestGetICS23Proof_RoundTrip(t *testing.T) {
+	leaves := generateLeaves(8)
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), leaves)
+	require.NoError(t, err)
+
+	leavesLength := len(leaves)
+	for i, leaf := range leaves {
+		proof, err := tree.GetICS23Proof(leavesLength - 1 + i)
+		require.NoError(t, err)
+		require.True(t, tree.VerifyICS23(tree.Root(), proof, []byte{}, leaf))
+	}
+}
+
+func TestGetICS23Proof_WrongValueFailsVerification(t *testing.T) {
+	leaves := generateLeaves(8)
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), leaves)
+	require.NoError(t, err)
+
+	leavesLength := len(leaves)
+	proof, err := tree.GetICS23Proof(leavesLength - 1)
+	require.NoError(t, err)
+	require.False(t, tree.VerifyICS23(tree.Root(), proof, []byte{}, leaves[1]))
+}
+
+func TestGetICS23Proof_RejectsRFC6962Trees(t *testing.T) {
+	leaves := generateLeaves(4)
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), leaves, ModeRFC6962)
+	require.NoError(t, err)
+
+	_, err = tree.GetICS23Proof(0)
+	require.Error(t, err)
+}
+
+func TestGetICS23Proof_RejectsNonLeafIndex(t *testing.T) {
+	leaves := generateLeaves(4)
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), leaves)
+	require.NoError(t, err)
+
+	_, err = tree.GetICS23Proof(0)
+	require.Error(t, err)
+}
+
+func TestGetICS23Proof_RejectsUnsupportedHasher(t *testing.T) {
+	leaves := generateLeaves(4)
+	tree, err := NewMerkeTree(sha512.New(), leaves)
+	require.NoError(t, err)
+
+	_, err = tree.GetICS23Proof(len(leaves) - 1)
+	require.Error(t, err)
+}
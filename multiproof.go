@@ -0,0 +1,125 @@
+package merkle_tree
+
+import (
+	"errors"
+	"sort"
+)
+
+// MultiProof is a compact proof authenticating several leaves at once.
+// Proof carries the deduplicated sibling hashes that cannot be derived
+// from the leaves being proven, and Flags tells the verifier, at each
+// hashing step, whether the next sibling comes from Proof or from the
+// hash stack built up so far.
+type MultiProof struct {
+	Proof []Node
+	Flags []bool
+}
+
+// GetMultiProof builds a MultiProof authenticating the leaves at indices.
+// indices may be given in any order but must not contain duplicates.
+// The leaves later passed to ProcessMultiProof must be supplied sorted
+// by descending index, matching the order indices ends up in here.
+func (tree *Tree) GetMultiProof(indices []int) (MultiProof, error) {
+	if tree.Mode == ModeRFC6962 {
+		return MultiProof{}, errors.New("multi-proof is not supported for RFC 6962 trees")
+	}
+	if len(indices) == 0 {
+		return MultiProof{}, errors.New("empty indices")
+	}
+
+	seen := make(map[int]bool, len(indices))
+	for _, i := range indices {
+		if !isLeafNode(tree, i) {
+			return MultiProof{}, errors.New("not a leaf node")
+		}
+		if seen[i] {
+			return MultiProof{}, errors.New("duplicate index")
+		}
+		seen[i] = true
+	}
+
+	stack := append([]int{}, indices...)
+	sort.Sort(sort.Reverse(sort.IntSlice(stack)))
+
+	proof := make([]Node, 0)
+	flags := make([]bool, 0)
+
+	for len(stack) > 0 && stack[0] > 0 {
+		j := stack[0]
+		stack = stack[1:]
+
+		sibIndex, _ := siblingIndex(j)
+		parIndex, _ := parentIndex(j)
+
+		if len(stack) > 0 && stack[0] == sibIndex {
+			flags = append(flags, true)
+			stack = stack[1:]
+		} else {
+			sib, err := tree.nodeAt(sibIndex)
+			if err != nil {
+				return MultiProof{}, err
+			}
+			flags = append(flags, false)
+			proof = append(proof, sib)
+		}
+		stack = append(stack, parIndex)
+	}
+
+	return MultiProof{Proof: proof, Flags: flags}, nil
+}
+
+// ProcessMultiProof recomputes the root from leaves (sorted by descending
+// index, as produced by GetMultiProof) and proof. The final value left on
+// the hash stack must equal the tree's root for the leaves to be valid.
+func (tree *Tree) ProcessMultiProof(leaves []Node, proof MultiProof) (Node, error) {
+	if len(leaves) == 0 {
+		return nil, errors.New("empty leaves")
+	}
+
+	for _, leaf := range leaves {
+		if !isValidMerkleNode(leaf) {
+			return nil, errors.New("not a merkle node")
+		}
+	}
+	for _, p := range proof.Proof {
+		if !isValidMerkleNode(p) {
+			return nil, errors.New("not a merkle node")
+		}
+	}
+
+	stack := append([]Node{}, leaves...)
+	proofNodes := append([]Node{}, proof.Proof...)
+
+	for _, flag := range proof.Flags {
+		if len(stack) == 0 {
+			return nil, errors.New("invalid multi proof")
+		}
+		a := stack[0]
+		stack = stack[1:]
+
+		var b Node
+		if flag {
+			if len(stack) == 0 {
+				return nil, errors.New("invalid multi proof")
+			}
+			b = stack[0]
+			stack = stack[1:]
+		} else {
+			if len(proofNodes) == 0 {
+				return nil, errors.New("invalid multi proof")
+			}
+			b = proofNodes[0]
+			proofNodes = proofNodes[1:]
+		}
+		stack = append(stack, tree.hashPair(a, b))
+	}
+
+	if len(stack) == 1 && len(proofNodes) == 0 {
+		return stack[0], nil
+	}
+	if len(stack) == 0 && len(proofNodes) == 1 {
+		return proofNodes[0], nil
+	}
+
+	return nil, errors.New("invalid multi proof")
+}
@@ -0,0 +1,70 @@
+package merkle_tree
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestMultiProof_NofM(t *testing.T) {
+	const m = 8
+	leaves := generateLeaves(m)
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), leaves)
+	require.NoError(t, err)
+
+	leafBeginIndex := len(tree.Nodes) / 2
+
+	for n := 2; n <= m; n++ {
+		indices := make([]int, n)
+		for k := 0; k < n; k++ {
+			indices[k] = leafBeginIndex + k
+		}
+
+		proof, err := tree.GetMultiProof(indices)
+		require.NoError(t, err)
+
+		sortedIndices := append([]int{}, indices...)
+		sort.Sort(sort.Reverse(sort.IntSlice(sortedIndices)))
+
+		provenLeaves := make([]Node, n)
+		for k, idx := range sortedIndices {
+			provenLeaves[k] = tree.Nodes[idx]
+		}
+
+		root, err := tree.ProcessMultiProof(provenLeaves, proof)
+		require.NoError(t, err)
+		require.Equal(t, tree.Nodes[0], root)
+
+		singleProofSize := 0
+		for _, idx := range indices {
+			p, err := tree.GetProofByIndex(idx)
+			require.NoError(t, err)
+			singleProofSize += len(p)
+		}
+
+		if n > 1 {
+			require.Less(t, len(proof.Proof), singleProofSize)
+		}
+	}
+}
+
+func TestMultiProof_NotLeaf(t *testing.T) {
+	leaves := generateLeaves(8)
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), leaves)
+	require.NoError(t, err)
+
+	_, err = tree.GetMultiProof([]int{0})
+	require.Error(t, err)
+}
+
+func TestMultiProof_DuplicateIndex(t *testing.T) {
+	leaves := generateLeaves(8)
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), leaves)
+	require.NoError(t, err)
+
+	leafBeginIndex := len(tree.Nodes) / 2
+	_, err = tree.GetMultiProof([]int{leafBeginIndex, leafBeginIndex})
+	require.Error(t, err)
+}
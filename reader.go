@@ -0,0 +1,194 @@
+package merkle_tree
+
+import (
+	"errors"
+	"hash"
+	"io"
+)
+
+// stackEntry is one partially-folded subtree held during an online
+// Merkle build: height counts levels above the leaves, node is its
+// hash.
+type stackEntry struct {
+	height int
+	node   Node
+}
+
+// foldLeaf pushes a new leaf onto stack and merges it with the top of
+// stack for as long as the top is the same height, the classic
+// online/streaming Merkle construction: two same-height subtrees are
+// folded into one the moment both exist.
+func foldLeaf(tree *Tree, stack []stackEntry, leaf Node) []stackEntry {
+	entry := stackEntry{height: 0, node: leaf}
+	for len(stack) > 0 && stack[len(stack)-1].height == entry.height {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		entry = stackEntry{height: entry.height + 1, node: tree.hashPair(top.node, entry.node)}
+	}
+	return append(stack, entry)
+}
+
+// flushStack combines the remaining unpaired subtrees at EOF into a
+// single root. Stack entries run largest/earliest first, so folding
+// proceeds right to left.
+func flushStack(tree *Tree, stack []stackEntry) Node {
+	root := stack[len(stack)-1].node
+	for i := len(stack) - 2; i >= 0; i-- {
+		root = tree.hashPair(stack[i].node, root)
+	}
+	return root
+}
+
+// BuildReaderTree Merkle-hashes r in segmentSize-byte chunks without
+// requiring the caller to pre-build a []Node slice of leaves. Leaves
+// are hashed and folded into subtrees as soon as two subtrees of equal
+// height are available. Only one segment of r's data is held at a
+// time; every leaf hash is kept, since the returned Tree holds them.
+//
+// When r yields a power-of-two number of segments, the result is a
+// regular Tree supporting GetProofByIndex like any other. For other
+// leaf counts, Nodes holds only the root followed by the leaves, so
+// Root still returns the root; proofs for those trees must be obtained
+// via BuildReaderProof.
+func BuildReaderTree(r io.Reader, hasher hash.Hash, segmentSize int) (*Tree, error) {
+	if hasher == nil {
+		return nil, errors.New("no hasher")
+	}
+	if segmentSize <= 0 {
+		return nil, errors.New("invalid segment size")
+	}
+
+	tree := &Tree{Hasher: hasher}
+
+	var stack []stackEntry
+	var leaves []Node
+	buf := make([]byte, segmentSize)
+
+	for {
+		n, rerr := io.ReadFull(r, buf)
+		if n > 0 {
+			hasher.Reset()
+			hasher.Write(buf[:n])
+			leaf := hasher.Sum(nil)
+			leaves = append(leaves, leaf)
+			stack = foldLeaf(tree, stack, leaf)
+		}
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rerr != nil {
+			return nil, rerr
+		}
+	}
+
+	if len(leaves) == 0 {
+		return nil, errors.New("empty reader")
+	}
+
+	if isPowerOf2(len(leaves)) {
+		return NewMerkeTree(hasher, leaves)
+	}
+
+	root := flushStack(tree, stack)
+	tree.Nodes = append([]Node{root}, leaves...)
+	return tree, nil
+}
+
+// BuildReaderProof streams r exactly like BuildReaderTree but also
+// tracks the sibling path for the leaf at proofIndex as it goes,
+// returning the root, that leaf's proof, and the total leaf count
+// without ever materializing the whole tree.
+func BuildReaderProof(r io.Reader, hasher hash.Hash, segmentSize int, proofIndex uint64) (Node, []Node, uint64, error) {
+	if hasher == nil {
+		return nil, nil, 0, errors.New("no hasher")
+	}
+	if segmentSize <= 0 {
+		return nil, nil, 0, errors.New("invalid segment size")
+	}
+
+	tree := &Tree{Hasher: hasher}
+
+	var stack []stackEntry
+	var proof []Node
+	var numLeaves uint64
+
+	// targetHeight tracks the height of the subtree containing the
+	// proof leaf; targetIsEntry says whether that subtree is the
+	// in-flight `entry` being folded (true) or already resting in
+	// stack (false). -1 means the target hasn't been seen yet.
+	targetHeight := -1
+	targetIsEntry := false
+
+	buf := make([]byte, segmentSize)
+
+	for {
+		n, rerr := io.ReadFull(r, buf)
+		if n > 0 {
+			hasher.Reset()
+			hasher.Write(buf[:n])
+			leaf := hasher.Sum(nil)
+
+			entry := stackEntry{height: 0, node: leaf}
+			if numLeaves == proofIndex {
+				targetHeight = 0
+				targetIsEntry = true
+			}
+
+			for len(stack) > 0 && stack[len(stack)-1].height == entry.height {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				h := entry.height
+
+				recordTarget := targetHeight == h
+				var sibling Node
+				if recordTarget {
+					if targetIsEntry {
+						sibling = top.node
+					} else {
+						sibling = entry.node
+					}
+				}
+
+				entry = stackEntry{height: h + 1, node: tree.hashPair(top.node, entry.node)}
+
+				if recordTarget {
+					proof = append(proof, sibling)
+					targetHeight = h + 1
+					targetIsEntry = true
+				}
+			}
+			if targetHeight == entry.height && targetIsEntry {
+				targetIsEntry = false
+			}
+			stack = append(stack, entry)
+			numLeaves++
+		}
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rerr != nil {
+			return nil, nil, 0, rerr
+		}
+	}
+
+	if numLeaves == 0 {
+		return nil, nil, 0, errors.New("empty reader")
+	}
+	if proofIndex >= numLeaves {
+		return nil, nil, 0, errors.New("proof index out of range")
+	}
+
+	running := stack[len(stack)-1].node
+	targetInRunning := stack[len(stack)-1].height == targetHeight
+	for i := len(stack) - 2; i >= 0; i-- {
+		if targetInRunning {
+			proof = append(proof, stack[i].node)
+		} else if stack[i].height == targetHeight {
+			proof = append(proof, running)
+			targetInRunning = true
+		}
+		running = tree.hashPair(stack[i].node, running)
+	}
+
+	return running, proof, numLeaves, nil
+}
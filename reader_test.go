@@ -0,0 +1,65 @@
+package merkle_tree
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestBuildReaderTree_PowerOf2(t *testing.T) {
+	data := strings.Repeat("a", 4*8)
+	tree, err := BuildReaderTree(strings.NewReader(data), sha3.NewLegacyKeccak256(), 4)
+	require.NoError(t, err)
+	require.True(t, tree.Verify())
+}
+
+func TestBuildReaderTree_EmptyReader(t *testing.T) {
+	_, err := BuildReaderTree(strings.NewReader(""), sha3.NewLegacyKeccak256(), 4)
+	require.Error(t, err)
+}
+
+// verifyReaderProof walks a BuildReaderProof result back up to the
+// root, following index's bits to know each sibling's side.
+func verifyReaderProof(tree *Tree, leaf Node, index uint64, proof []Node, root Node) bool {
+	node := leaf
+	for _, sib := range proof {
+		if index%2 == 0 {
+			node = tree.hashPair(node, sib)
+		} else {
+			node = tree.hashPair(sib, node)
+		}
+		index /= 2
+	}
+	return bytes.Equal(node, root)
+}
+
+func TestBuildReaderProof_NonPowerOf2Sizes(t *testing.T) {
+	tree := &Tree{Hasher: sha3.NewLegacyKeccak256()}
+
+	for numLeaves := 1; numLeaves <= 12; numLeaves++ {
+		segSize := 4
+		data := strings.Repeat("x", segSize*numLeaves)
+
+		for idx := uint64(0); idx < uint64(numLeaves); idx++ {
+			root, proof, n, err := BuildReaderProof(strings.NewReader(data), sha3.NewLegacyKeccak256(), segSize, idx)
+			require.NoError(t, err, "numLeaves=%d idx=%d", numLeaves, idx)
+			require.Equal(t, uint64(numLeaves), n)
+
+			hasher := sha3.NewLegacyKeccak256()
+			hasher.Write([]byte(data[idx*uint64(segSize) : (idx+1)*uint64(segSize)]))
+			leaf := hasher.Sum(nil)
+
+			require.True(t, verifyReaderProof(tree, leaf, idx, proof, root),
+				fmt.Sprintf("numLeaves=%d idx=%d", numLeaves, idx))
+		}
+	}
+}
+
+func TestBuildReaderProof_IndexOutOfRange(t *testing.T) {
+	_, _, _, err := BuildReaderProof(strings.NewReader("abcd"), sha3.NewLegacyKeccak256(), 4, 1)
+	require.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package merkle_tree
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+)
+
+// RFC6962LeafHash domain-separates a leaf with a 0x00 prefix, per
+// RFC 6962 / Certificate Transparency. Callers building a ModeRFC6962
+// tree must hash a leaf with this before passing it to GetProof or
+// ProcessProof, since Levels[0] holds domain-separated leaves, not raw
+// ones.
+func (tree *Tree) RFC6962LeafHash(leaf Node) Node {
+	tree.Hasher.Reset()
+	tree.Hasher.Write([]byte{0x00})
+	tree.Hasher.Write(leaf)
+	return tree.Hasher.Sum(nil)
+}
+
+// rfc6962NodeHash domain-separates an internal node with a 0x01 prefix
+// and hashes left||right in fixed order, unlike Tree.hashPair's sorted
+// pair rule.
+func (tree *Tree) rfc6962NodeHash(left, right Node) Node {
+	tree.Hasher.Reset()
+	tree.Hasher.Write([]byte{0x01})
+	tree.Hasher.Write(left)
+	tree.Hasher.Write(right)
+	return tree.Hasher.Sum(nil)
+}
+
+// newRFC6962Tree builds a tree level by level, pairing consecutive
+// nodes and, when a level has an odd count, promoting the unpaired
+// trailing node to the next level unchanged. This supports any leaf
+// count, not just powers of two.
+func newRFC6962Tree(hasher hash.Hash, leaves []Node) (*Tree, error) {
+	tree := &Tree{Hasher: hasher, Mode: ModeRFC6962}
+
+	level := make([]Node, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = tree.RFC6962LeafHash(leaf)
+	}
+	levels := [][]Node{level}
+
+	for len(level) > 1 {
+		next := make([]Node, 0, (len(level)+1)/2)
+		for i := 0; i+1 < len(level); i += 2 {
+			next = append(next, tree.rfc6962NodeHash(level[i], level[i+1]))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	tree.Levels = levels
+	return tree, nil
+}
+
+// getRFC6962ProofByIndex climbs Levels from leaf i to the root,
+// recording a sibling (with its side) at every level that has one. A
+// level contributes no proof step for a node that was promoted
+// unpaired.
+func (tree *Tree) getRFC6962ProofByIndex(i int) ([]ProofStep, error) {
+	if len(tree.Levels) == 0 || i < 0 || i >= len(tree.Levels[0]) {
+		return nil, errors.New("not a leaf node")
+	}
+
+	proof := make([]ProofStep, 0)
+	idx := i
+	for level := 0; level < len(tree.Levels)-1; level++ {
+		nodes := tree.Levels[level]
+		if idx%2 == 1 {
+			proof = append(proof, ProofStep{Node: nodes[idx-1], OnRight: false})
+		} else if idx+1 < len(nodes) {
+			proof = append(proof, ProofStep{Node: nodes[idx+1], OnRight: true})
+		}
+		idx = idx / 2
+	}
+	return proof, nil
+}
+
+// isValidRFC6962Tree checks that every level was folded correctly into
+// the next, including odd-node promotion, down to a single root.
+func isValidRFC6962Tree(tree *Tree) bool {
+	if len(tree.Levels) == 0 {
+		return false
+	}
+
+	for _, level := range tree.Levels {
+		for _, node := range level {
+			if !isValidMerkleNode(node) {
+				return false
+			}
+		}
+	}
+
+	for l := 0; l < len(tree.Levels)-1; l++ {
+		level := tree.Levels[l]
+		next := tree.Levels[l+1]
+
+		expected := (len(level) + 1) / 2
+		if len(next) != expected {
+			return false
+		}
+
+		for i := 0; i+1 < len(level); i += 2 {
+			if bytes.Compare(next[i/2], tree.rfc6962NodeHash(level[i], level[i+1])) != 0 {
+				return false
+			}
+		}
+		if len(level)%2 == 1 {
+			if bytes.Compare(next[len(next)-1], level[len(level)-1]) != 0 {
+				return false
+			}
+		}
+	}
+
+	return len(tree.Levels[len(tree.Levels)-1]) == 1
+}
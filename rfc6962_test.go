@@ -0,0 +1,69 @@
+package merkle_tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestRFC6962Tree_OddLeafCount(t *testing.T) {
+	leaves := generateLeaves(5)
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), leaves, ModeRFC6962)
+	require.NoError(t, err)
+	require.True(t, tree.Verify())
+
+	for i, leaf := range leaves {
+		proof, err := tree.GetProofByIndex(i)
+		require.NoError(t, err)
+
+		leafHash := tree.RFC6962LeafHash(leaf)
+		root, err := tree.ProcessProof(leafHash, proof)
+		require.NoError(t, err)
+		require.Equal(t, tree.Root(), root)
+	}
+}
+
+func TestRFC6962Tree_PowerOf2LeafCount(t *testing.T) {
+	leaves := generateLeaves(8)
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), leaves, ModeRFC6962)
+	require.NoError(t, err)
+	require.True(t, tree.Verify())
+
+	leafHash := tree.RFC6962LeafHash(leaves[5])
+	proof, err := tree.GetProof(leafHash)
+	require.NoError(t, err)
+
+	root, err := tree.ProcessProof(leafHash, proof)
+	require.NoError(t, err)
+	require.Equal(t, tree.Root(), root)
+}
+
+func TestRFC6962Tree_SingleLeaf(t *testing.T) {
+	leaves := generateLeaves(1)
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), leaves, ModeRFC6962)
+	require.NoError(t, err)
+	require.True(t, tree.Verify())
+
+	proof, err := tree.GetProofByIndex(0)
+	require.NoError(t, err)
+	require.Empty(t, proof)
+	require.Equal(t, tree.Root(), tree.RFC6962LeafHash(leaves[0]))
+}
+
+func TestNewMerkeTree_DefaultModeUnchanged(t *testing.T) {
+	leaves := generateLeaves(4)
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), leaves)
+	require.NoError(t, err)
+	require.Equal(t, ModeSortedPair, tree.Mode)
+	require.Equal(t, tree.Nodes[0], tree.Root())
+}
+
+func TestGetMultiProof_RFC6962Unsupported(t *testing.T) {
+	leaves := generateLeaves(4)
+	tree, err := NewMerkeTree(sha3.NewLegacyKeccak256(), leaves, ModeRFC6962)
+	require.NoError(t, err)
+
+	_, err = tree.GetMultiProof([]int{0, 1})
+	require.Error(t, err)
+}
@@ -0,0 +1,256 @@
+package merkle_tree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// sparseDepth is the number of levels in a SparseTree: one per bit of a
+// [DigestLength]byte key.
+const sparseDepth = DigestLength * 8
+
+// SparseProof authenticates a key's value (or its absence) in a
+// SparseTree. Siblings holds only the non-default sibling hashes, in
+// leaf-to-root order; Bitmap has one entry per level and tells the
+// verifier whether that level's sibling is the next entry in Siblings
+// (true) or the cached default hash for that level (false).
+type SparseProof struct {
+	Siblings []Node
+	Bitmap   []bool
+}
+
+// SparseTree is a fixed-depth sparse Merkle tree keyed by
+// [DigestLength]byte, used as an authenticated key-value store where
+// keys can be proven present or absent. Unlike Tree, which requires a
+// power-of-two leaf count, every one of the 2^sparseDepth possible keys
+// exists implicitly, defaulting to an all-zero leaf.
+type SparseTree struct {
+	Hasher hash.Hash
+	Depth  int
+
+	// defaultHashes[h] is the root of an empty subtree of height h
+	// (defaultHashes[0] is the zero leaf).
+	defaultHashes []Node
+	nodes         map[string]Node
+}
+
+// NewSparseTree builds an empty sparse tree and pre-computes the
+// default hash of an empty subtree at every level, so unvisited
+// branches never need to be materialized.
+func NewSparseTree(hasher hash.Hash) (*SparseTree, error) {
+	if hasher == nil {
+		return nil, errors.New("no hasher")
+	}
+
+	tree := &SparseTree{
+		Hasher: hasher,
+		Depth:  sparseDepth,
+		nodes:  make(map[string]Node),
+	}
+
+	defaultHashes := make([]Node, sparseDepth+1)
+	defaultHashes[0] = make(Node, DigestLength)
+	for h := 1; h <= sparseDepth; h++ {
+		defaultHashes[h] = tree.hashPair(defaultHashes[h-1], defaultHashes[h-1])
+	}
+	tree.defaultHashes = defaultHashes
+
+	return tree, nil
+}
+
+// hashPair hashes two children in fixed left/right order. Unlike
+// Tree.hashPair it does not sort the pair: a sparse tree's node
+// position is determined by the key's bits, not by the hash values.
+func (t *SparseTree) hashPair(left, right Node) Node {
+	t.Hasher.Reset()
+	buff := bytes.Buffer{}
+	buff.Write(left)
+	buff.Write(right)
+	t.Hasher.Write(buff.Bytes())
+	return t.Hasher.Sum(nil)
+}
+
+func bitAt(key [DigestLength]byte, d int) bool {
+	return (key[d/8]>>(7-uint(d%8)))&1 == 1
+}
+
+// pathPrefix returns the first d bits of key as a string of '0'/'1'
+// characters, used to address a node d levels below the root.
+func pathPrefix(key [DigestLength]byte, d int) string {
+	buf := make([]byte, d)
+	for i := 0; i < d; i++ {
+		if bitAt(key, i) {
+			buf[i] = '1'
+		} else {
+			buf[i] = '0'
+		}
+	}
+	return string(buf)
+}
+
+func nodeKey(depth int, prefix string) string {
+	return fmt.Sprintf("%d:%s", depth, prefix)
+}
+
+// Root returns the current root hash of the tree.
+func (t *SparseTree) Root() Node {
+	if n, ok := t.nodes[nodeKey(0, "")]; ok {
+		return n
+	}
+	return t.defaultHashes[t.Depth]
+}
+
+// Update sets the leaf at key to value and rehashes every node on the
+// path from that leaf up to the root.
+func (t *SparseTree) Update(key [DigestLength]byte, value Node) error {
+	if !isValidMerkleNode(value) {
+		return errors.New("not a merkle node")
+	}
+
+	t.nodes[nodeKey(t.Depth, pathPrefix(key, t.Depth))] = value
+
+	cur := value
+	for d := t.Depth - 1; d >= 0; d-- {
+		prefix := pathPrefix(key, d)
+		bit := bitAt(key, d)
+
+		siblingPrefix := prefix
+		if bit {
+			siblingPrefix += "0"
+		} else {
+			siblingPrefix += "1"
+		}
+
+		sibling, ok := t.nodes[nodeKey(d+1, siblingPrefix)]
+		if !ok {
+			sibling = t.defaultHashes[t.Depth-(d+1)]
+		}
+
+		var parent Node
+		if bit {
+			parent = t.hashPair(sibling, cur)
+		} else {
+			parent = t.hashPair(cur, sibling)
+		}
+
+		t.nodes[nodeKey(d, prefix)] = parent
+		cur = parent
+	}
+
+	return nil
+}
+
+// Get returns the leaf stored at key, or ok=false if key was never
+// updated (a key updated to the zero value reports ok=true).
+func (t *SparseTree) Get(key [DigestLength]byte) (Node, bool) {
+	n, ok := t.nodes[nodeKey(t.Depth, pathPrefix(key, t.Depth))]
+	if !ok {
+		return nil, false
+	}
+	return n, true
+}
+
+// Prove builds a SparseProof for key, usable both to prove membership
+// (recompute with the known leaf value) and non-membership (recompute
+// with a zero leaf) via VerifyMembership / VerifyNonMembership.
+func (t *SparseTree) Prove(key [DigestLength]byte) (SparseProof, error) {
+	proof := SparseProof{
+		Siblings: make([]Node, 0),
+		Bitmap:   make([]bool, t.Depth),
+	}
+
+	for d := t.Depth - 1; d >= 0; d-- {
+		idx := t.Depth - 1 - d
+		prefix := pathPrefix(key, d)
+		bit := bitAt(key, d)
+
+		siblingPrefix := prefix
+		if bit {
+			siblingPrefix += "0"
+		} else {
+			siblingPrefix += "1"
+		}
+
+		if sibling, ok := t.nodes[nodeKey(d+1, siblingPrefix)]; ok {
+			proof.Bitmap[idx] = true
+			proof.Siblings = append(proof.Siblings, sibling)
+		}
+	}
+
+	return proof, nil
+}
+
+// recomputeRoot replays a SparseProof starting from leafValue at key,
+// substituting the cached default hash wherever Bitmap marks a level
+// as not carrying an explicit sibling.
+func (t *SparseTree) recomputeRoot(key [DigestLength]byte, leafValue Node, proof SparseProof) (Node, error) {
+	if len(proof.Bitmap) != t.Depth {
+		return nil, errors.New("invalid sparse proof")
+	}
+
+	cur := leafValue
+	siblingIdx := 0
+	for i := 0; i < t.Depth; i++ {
+		d := t.Depth - 1 - i
+		bit := bitAt(key, d)
+
+		var sibling Node
+		if proof.Bitmap[i] {
+			if siblingIdx >= len(proof.Siblings) {
+				return nil, errors.New("invalid sparse proof")
+			}
+			sibling = proof.Siblings[siblingIdx]
+			siblingIdx++
+		} else {
+			sibling = t.defaultHashes[t.Depth-(d+1)]
+		}
+
+		if bit {
+			cur = t.hashPair(sibling, cur)
+		} else {
+			cur = t.hashPair(cur, sibling)
+		}
+	}
+
+	if siblingIdx != len(proof.Siblings) {
+		return nil, errors.New("invalid sparse proof")
+	}
+
+	return cur, nil
+}
+
+// VerifyMembership reports whether proof authenticates value at key
+// against root.
+func (t *SparseTree) VerifyMembership(root, key, value Node, proof SparseProof) bool {
+	if len(key) != DigestLength || !isValidMerkleNode(value) {
+		return false
+	}
+
+	var k [DigestLength]byte
+	copy(k[:], key)
+
+	computed, err := t.recomputeRoot(k, value, proof)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(computed, root)
+}
+
+// VerifyNonMembership reports whether proof authenticates that key
+// holds its default (never-updated) value against root.
+func (t *SparseTree) VerifyNonMembership(root, key Node, proof SparseProof) bool {
+	if len(key) != DigestLength {
+		return false
+	}
+
+	var k [DigestLength]byte
+	copy(k[:], key)
+
+	computed, err := t.recomputeRoot(k, make(Node, DigestLength), proof)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(computed, root)
+}
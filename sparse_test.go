@@ -0,0 +1,72 @@
+package merkle_tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func sparseKey(b byte) [DigestLength]byte {
+	var k [DigestLength]byte
+	k[DigestLength-1] = b
+	return k
+}
+
+func TestSparseTree_UpdateAndGet(t *testing.T) {
+	tree, err := NewSparseTree(sha3.NewLegacyKeccak256())
+	require.NoError(t, err)
+
+	key := sparseKey(1)
+	value := generateLeaves(1)[0]
+
+	_, ok := tree.Get(key)
+	require.False(t, ok)
+
+	require.NoError(t, tree.Update(key, value))
+
+	got, ok := tree.Get(key)
+	require.True(t, ok)
+	require.Equal(t, value, got)
+}
+
+func TestSparseTree_MembershipProof(t *testing.T) {
+	tree, err := NewSparseTree(sha3.NewLegacyKeccak256())
+	require.NoError(t, err)
+
+	key := sparseKey(7)
+	value := generateLeaves(1)[0]
+	require.NoError(t, tree.Update(key, value))
+
+	proof, err := tree.Prove(key)
+	require.NoError(t, err)
+	require.True(t, tree.VerifyMembership(tree.Root(), Node(key[:]), value, proof))
+}
+
+func TestSparseTree_NonMembershipProof(t *testing.T) {
+	tree, err := NewSparseTree(sha3.NewLegacyKeccak256())
+	require.NoError(t, err)
+
+	leaves := generateLeaves(2)
+	require.NoError(t, tree.Update(sparseKey(1), leaves[0]))
+
+	absentKey := sparseKey(2)
+	_, ok := tree.Get(absentKey)
+	require.False(t, ok)
+
+	proof, err := tree.Prove(absentKey)
+	require.NoError(t, err)
+	require.True(t, tree.VerifyNonMembership(tree.Root(), Node(absentKey[:]), proof))
+
+	require.NoError(t, tree.Update(absentKey, leaves[1]))
+	require.False(t, tree.VerifyNonMembership(tree.Root(), Node(absentKey[:]), proof))
+}
+
+func TestSparseTree_EmptyTreeRoot(t *testing.T) {
+	a, err := NewSparseTree(sha3.NewLegacyKeccak256())
+	require.NoError(t, err)
+	b, err := NewSparseTree(sha3.NewLegacyKeccak256())
+	require.NoError(t, err)
+
+	require.Equal(t, a.Root(), b.Root())
+}
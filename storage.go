@@ -0,0 +1,107 @@
+package merkle_tree
+
+import (
+	"errors"
+	"hash"
+)
+
+// ErrNodeNotFound is returned by a Storage backend when a node hash is
+// not present.
+var ErrNodeNotFound = errors.New("node not found")
+
+// Storage is a pluggable persistent backend for a Tree's nodes. Nodes
+// are addressed by their own hash, so a given node is written once and
+// can be shared structurally between trees that happen to contain it.
+type Storage interface {
+	Get(key []byte) ([]byte, error)
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	Batch() Batch
+}
+
+// Batch groups writes so a backend can flush them together. Tree
+// construction writes one entry per node, so batching matters for
+// throughput on real backends.
+type Batch interface {
+	Put(key, value []byte)
+	Delete(key []byte)
+	Write() error
+}
+
+// nodeStorageValue is what gets written under a node's own hash: empty
+// for a leaf, or the concatenated left||right child hashes for an
+// internal node, so a tree from LoadTree can be walked back down from
+// the root (see nodeAt).
+func nodeStorageValue(tree *Tree, i int) []byte {
+	if isLeafNode(tree, i) {
+		return []byte{}
+	}
+	l, _ := tree.nodeAt(leftChildIndex(i))
+	r, _ := tree.nodeAt(rightChildIndex(i))
+	v := make([]byte, 0, len(l)+len(r))
+	v = append(v, l...)
+	v = append(v, r...)
+	return v
+}
+
+// NewMerkleTreeWithStorage builds a tree exactly like NewMerkeTree, then
+// persists every node into storage in a single batch, keyed by hash.
+func NewMerkleTreeWithStorage(hasher hash.Hash, storage Storage, leaves []Node) (*Tree, error) {
+	if storage == nil {
+		return nil, errors.New("no storage")
+	}
+
+	tree, err := NewMerkeTree(hasher, leaves)
+	if err != nil {
+		return nil, err
+	}
+	tree.Storage = storage
+
+	batch := storage.Batch()
+	for i := range tree.Nodes {
+		node, err := tree.nodeAt(i)
+		if err != nil {
+			return nil, err
+		}
+		batch.Put(node, nodeStorageValue(tree, i))
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// LoadTree reconstructs a tree from storage given its root hash and
+// leafCount, the tree's leaf count (needed to size Nodes, since that
+// can no longer be learned by walking the tree). Only the root is
+// resolved up front; everything below it is fetched lazily by nodeAt
+// the first time a caller reads that far, so loading a tree too large
+// to fit in memory costs only what's read afterwards, not the whole
+// tree. Each node is checked against its parent's hash the first time
+// it's read (see nodeAt), so corruption is caught on the path that
+// reaches it rather than requiring a full upfront walk to detect at
+// all.
+func LoadTree(hasher hash.Hash, storage Storage, rootHash Node, leafCount int) (*Tree, error) {
+	if storage == nil {
+		return nil, errors.New("no storage")
+	}
+	if !isValidMerkleNode(rootHash) {
+		return nil, errors.New("not a merkle node")
+	}
+	if leafCount <= 0 || !isPowerOf2(leafCount) {
+		return nil, errors.New("only support complete binary tree")
+	}
+	if _, err := storage.Get(rootHash); err != nil {
+		return nil, err
+	}
+
+	tree := &Tree{
+		Hasher:  hasher,
+		Nodes:   make([]Node, 2*leafCount-1),
+		Storage: storage,
+	}
+	tree.Nodes[0] = rootHash
+
+	return tree, nil
+}
@@ -0,0 +1,51 @@
+package merkle_tree
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// LevelDBStorage adapts a goleveldb database to the Storage interface.
+type LevelDBStorage struct {
+	db *leveldb.DB
+}
+
+func NewLevelDBStorage(db *leveldb.DB) *LevelDBStorage {
+	return &LevelDBStorage{db: db}
+}
+
+func (s *LevelDBStorage) Get(key []byte) ([]byte, error) {
+	v, err := s.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, ErrNodeNotFound
+	}
+	return v, err
+}
+
+func (s *LevelDBStorage) Put(key, value []byte) error {
+	return s.db.Put(key, value, nil)
+}
+
+func (s *LevelDBStorage) Delete(key []byte) error {
+	return s.db.Delete(key, nil)
+}
+
+func (s *LevelDBStorage) Batch() Batch {
+	return &levelDBBatch{db: s.db, batch: new(leveldb.Batch)}
+}
+
+type levelDBBatch struct {
+	db    *leveldb.DB
+	batch *leveldb.Batch
+}
+
+func (b *levelDBBatch) Put(key, value []byte) {
+	b.batch.Put(key, value)
+}
+
+func (b *levelDBBatch) Delete(key []byte) {
+	b.batch.Delete(key)
+}
+
+func (b *levelDBBatch) Write() error {
+	return b.db.Write(b.batch, nil)
+}
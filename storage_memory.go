@@ -0,0 +1,74 @@
+package merkle_tree
+
+import "sync"
+
+// MemStorage is an in-memory Storage backed by a map. It is mainly
+// useful for tests, or for callers that want the Storage-backed code
+// paths (LoadTree, content-addressed keys) without a real on-disk
+// backend.
+type MemStorage struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+func NewMemStorage() *MemStorage {
+	return &MemStorage{data: make(map[string][]byte)}
+}
+
+func (s *MemStorage) Get(key []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.data[string(key)]
+	if !ok {
+		return nil, ErrNodeNotFound
+	}
+	return v, nil
+}
+
+func (s *MemStorage) Put(key, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[string(key)] = value
+	return nil
+}
+
+func (s *MemStorage) Delete(key []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, string(key))
+	return nil
+}
+
+func (s *MemStorage) Batch() Batch {
+	return &memBatch{store: s}
+}
+
+type memBatch struct {
+	store   *MemStorage
+	puts    [][2][]byte
+	deletes [][]byte
+}
+
+func (b *memBatch) Put(key, value []byte) {
+	b.puts = append(b.puts, [2][]byte{key, value})
+}
+
+func (b *memBatch) Delete(key []byte) {
+	b.deletes = append(b.deletes, key)
+}
+
+func (b *memBatch) Write() error {
+	b.store.mu.Lock()
+	defer b.store.mu.Unlock()
+
+	for _, kv := range b.puts {
+		b.store.data[string(kv[0])] = kv[1]
+	}
+	for _, k := range b.deletes {
+		delete(b.store.data, string(k))
+	}
+	return nil
+}
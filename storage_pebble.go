@@ -0,0 +1,57 @@
+package merkle_tree
+
+import (
+	"github.com/cockroachdb/pebble"
+)
+
+// PebbleStorage adapts a pebble database to the Storage interface.
+type PebbleStorage struct {
+	db *pebble.DB
+}
+
+func NewPebbleStorage(db *pebble.DB) *PebbleStorage {
+	return &PebbleStorage{db: db}
+}
+
+func (s *PebbleStorage) Get(key []byte) ([]byte, error) {
+	v, closer, err := s.db.Get(key)
+	if err == pebble.ErrNotFound {
+		return nil, ErrNodeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	out := make([]byte, len(v))
+	copy(out, v)
+	return out, nil
+}
+
+func (s *PebbleStorage) Put(key, value []byte) error {
+	return s.db.Set(key, value, pebble.Sync)
+}
+
+func (s *PebbleStorage) Delete(key []byte) error {
+	return s.db.Delete(key, pebble.Sync)
+}
+
+func (s *PebbleStorage) Batch() Batch {
+	return &pebbleBatch{batch: s.db.NewBatch()}
+}
+
+type pebbleBatch struct {
+	batch *pebble.Batch
+}
+
+func (b *pebbleBatch) Put(key, value []byte) {
+	_ = b.batch.Set(key, value, nil)
+}
+
+func (b *pebbleBatch) Delete(key []byte) {
+	_ = b.batch.Delete(key, nil)
+}
+
+func (b *pebbleBatch) Write() error {
+	return b.batch.Commit(pebble.Sync)
+}
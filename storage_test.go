@@ -0,0 +1,66 @@
+package merkle_tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestNewMerkleTreeWithStorage(t *testing.T) {
+	leaves := generateLeaves(8)
+	storage := NewMemStorage()
+
+	tree, err := NewMerkleTreeWithStorage(sha3.NewLegacyKeccak256(), storage, leaves)
+	require.NoError(t, err)
+	require.True(t, tree.Verify())
+
+	proof, err := tree.GetProofByIndex(7)
+	require.NoError(t, err)
+	root, err := tree.ProcessProof(tree.Nodes[7], proof)
+	require.NoError(t, err)
+	require.Equal(t, tree.Nodes[0], root)
+}
+
+func TestLoadTree(t *testing.T) {
+	leaves := generateLeaves(8)
+	storage := NewMemStorage()
+
+	tree, err := NewMerkleTreeWithStorage(sha3.NewLegacyKeccak256(), storage, leaves)
+	require.NoError(t, err)
+
+	loaded, err := LoadTree(sha3.NewLegacyKeccak256(), storage, tree.Nodes[0], len(leaves))
+	require.NoError(t, err)
+	require.Equal(t, tree.Nodes[0], loaded.Nodes[0])
+
+	leavesLength := len(leaves)
+	for i, leaf := range leaves {
+		proof, err := loaded.GetProofByIndex(leavesLength - 1 + i)
+		require.NoError(t, err)
+		root, err := loaded.ProcessProof(leaf, proof)
+		require.NoError(t, err)
+		require.Equal(t, loaded.Root(), root)
+	}
+
+	// Every node lies on some leaf's path to the root, so having just
+	// walked every leaf's proof has lazily resolved the whole tree.
+	require.True(t, loaded.Verify())
+	require.Equal(t, tree.Nodes, loaded.Nodes)
+}
+
+func TestLoadTree_UnknownRoot(t *testing.T) {
+	storage := NewMemStorage()
+	_, err := LoadTree(sha3.NewLegacyKeccak256(), storage, generateLeaves(1)[0], 1)
+	require.Error(t, err)
+}
+
+func TestLoadTree_RejectsNonPowerOfTwoLeafCount(t *testing.T) {
+	leaves := generateLeaves(8)
+	storage := NewMemStorage()
+
+	tree, err := NewMerkleTreeWithStorage(sha3.NewLegacyKeccak256(), storage, leaves)
+	require.NoError(t, err)
+
+	_, err = LoadTree(sha3.NewLegacyKeccak256(), storage, tree.Nodes[0], 3)
+	require.Error(t, err)
+}